@@ -0,0 +1,110 @@
+// Command discourse runs a registry node: a hash ring over a pluggable
+// backend, exposed to other nodes (and to peering importers) over gRPC.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"hash/fnv"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/SimonRichardson/alchemy/pkg/cluster/peering"
+	"github.com/SimonRichardson/alchemy/pkg/cluster/registry"
+	"github.com/SimonRichardson/alchemy/pkg/cluster/registry/backend/consul"
+	"github.com/SimonRichardson/alchemy/pkg/cluster/registry/backend/etcd"
+	"github.com/SimonRichardson/alchemy/pkg/cluster/registry/backend/memory"
+	"github.com/SimonRichardson/alchemy/pkg/cluster/registry/registrypb"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	consulapi "github.com/hashicorp/consul/api"
+	"github.com/pkg/errors"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"google.golang.org/grpc"
+)
+
+const defaultReplicationFactor = 3
+
+func main() {
+	var (
+		grpcAddr        = flag.String("grpc", ":7979", "gRPC listen address for RegistryStream")
+		registryBackend = flag.String("registry-backend", "memory", "registry backend to use: memory, etcd or consul")
+		etcdEndpoints   = flag.String("etcd-endpoints", "127.0.0.1:2379", "comma-separated etcd endpoints (registry-backend=etcd)")
+		etcdPrefix      = flag.String("etcd-prefix", "discourse", "key prefix the registry is stored under (registry-backend=etcd)")
+		etcdLeaseTTL    = flag.Duration("etcd-lease-ttl", etcd.DefaultLeaseTTL, "lease TTL for registered keys (registry-backend=etcd)")
+		consulAddr      = flag.String("consul-addr", "127.0.0.1:8500", "Consul HTTP address (registry-backend=consul)")
+		peeringSecret   = flag.String("peering-secret", "", "secret used to sign and validate peering tokens; unset means Watch is unauthenticated")
+	)
+	flag.Parse()
+
+	logger := log.NewLogfmtLogger(os.Stderr)
+
+	backend, err := newBackend(*registryBackend, *etcdEndpoints, *etcdPrefix, *etcdLeaseTTL, *consulAddr)
+	if err != nil {
+		level.Error(logger).Log("msg", "failed to construct registry backend", "backend", *registryBackend, "err", err)
+		os.Exit(1)
+	}
+
+	reg := registry.NewWithBackend(backend, hashFNV32a, defaultReplicationFactor)
+
+	listener, err := net.Listen("tcp", *grpcAddr)
+	if err != nil {
+		level.Error(logger).Log("msg", "failed to listen", "addr", *grpcAddr, "err", err)
+		os.Exit(1)
+	}
+
+	var auth registry.Authenticator
+	if *peeringSecret != "" {
+		auth = peering.NewTokenAuthenticator([]byte(*peeringSecret))
+	} else {
+		level.Warn(logger).Log("msg", "no -peering-secret set; RegistryStream.Watch is unauthenticated")
+	}
+
+	server := grpc.NewServer()
+	registrypb.RegisterRegistryStreamServer(server, registry.NewGRPCServer(reg, auth))
+
+	level.Info(logger).Log("msg", "listening", "addr", *grpcAddr, "registry-backend", *registryBackend)
+	if err := server.Serve(listener); err != nil {
+		level.Error(logger).Log("msg", "server stopped", "err", err)
+		os.Exit(1)
+	}
+}
+
+// newBackend constructs the registry.Backend named by name. A name of
+// "memory" returns a local, single-process backend (registry.New, by
+// contrast, runs with no backend at all); naming it explicitly lets an
+// operator pin it via the same flag used to pick etcd or consul.
+func newBackend(name, etcdEndpoints, etcdPrefix string, etcdLeaseTTL time.Duration, consulAddr string) (registry.Backend, error) {
+	switch name {
+	case "memory":
+		return memory.New(), nil
+
+	case "etcd":
+		client, err := clientv3.New(clientv3.Config{
+			Endpoints: strings.Split(etcdEndpoints, ","),
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "connect to etcd")
+		}
+		return etcd.New(client, etcdPrefix, etcdLeaseTTL), nil
+
+	case "consul":
+		client, err := consulapi.NewClient(&consulapi.Config{Address: consulAddr})
+		if err != nil {
+			return nil, errors.Wrap(err, "connect to consul")
+		}
+		return consul.New(client), nil
+
+	default:
+		return nil, fmt.Errorf("unknown registry backend %q: want memory, etcd or consul", name)
+	}
+}
+
+// hashFNV32a is the default hash function for the registry's hash ring.
+func hashFNV32a(data []byte) uint32 {
+	h := fnv.New32a()
+	_, _ = h.Write(data)
+	return h.Sum32()
+}
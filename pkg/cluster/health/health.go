@@ -0,0 +1,124 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/SimonRichardson/alchemy/pkg/cluster/registry"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+)
+
+// DefaultDeregisterCriticalAfter is how long a check may stay Critical
+// before its key is automatically removed from the registry.
+const DefaultDeregisterCriticalAfter = 72 * time.Hour
+
+// Manager runs active and passive health checks for registry keys and
+// feeds the observed status back in to the registry via SetStatus.
+type Manager struct {
+	registry registry.Registry
+	logger   log.Logger
+
+	mtx    sync.Mutex
+	checks map[string]*managedCheck
+}
+
+// NewManager creates a Manager bound to reg.
+func NewManager(reg registry.Registry, logger log.Logger) *Manager {
+	return &Manager{
+		registry: reg,
+		logger:   logger,
+		checks:   make(map[string]*managedCheck),
+	}
+}
+
+// managedCheck tracks the bookkeeping shared by every check kind: the key
+// it backs, how long it may stay Critical before deregistration, and the
+// cancellation for its background goroutine.
+type managedCheck struct {
+	key             registry.Key
+	deregisterAfter time.Duration
+	cancel          context.CancelFunc
+
+	// passCh carries TTL heartbeats; nil for HTTP and TCP checks.
+	passCh chan struct{}
+
+	mtx           sync.Mutex
+	criticalSince time.Time
+	lastStatus    registry.Status
+	lastOutput    string
+}
+
+// Deregister stops a check's background goroutine and forgets it, without
+// touching the key it was attached to.
+func (m *Manager) Deregister(id string) {
+	m.mtx.Lock()
+	mc, ok := m.checks[id]
+	if ok {
+		delete(m.checks, id)
+	}
+	m.mtx.Unlock()
+
+	if ok {
+		mc.cancel()
+	}
+}
+
+func (m *Manager) register(id string, mc *managedCheck) {
+	m.mtx.Lock()
+	m.checks[id] = mc
+	m.mtx.Unlock()
+}
+
+func (m *Manager) check(id string) (*managedCheck, bool) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	mc, ok := m.checks[id]
+	return mc, ok
+}
+
+// observe records status against the key backing id and, if it has been
+// Critical for longer than deregisterAfter, removes the key from the
+// registry and tears the check down.
+func (m *Manager) observe(id string, mc *managedCheck, status registry.Status, output string) {
+	m.registry.SetStatus(mc.key.Name(), status, output)
+
+	mc.mtx.Lock()
+	mc.lastStatus = status
+	mc.lastOutput = output
+	if status != registry.Critical {
+		mc.criticalSince = time.Time{}
+		mc.mtx.Unlock()
+		return
+	}
+
+	if mc.criticalSince.IsZero() {
+		mc.criticalSince = time.Now()
+		mc.mtx.Unlock()
+		return
+	}
+
+	elapsed := time.Since(mc.criticalSince)
+	mc.mtx.Unlock()
+
+	if elapsed < mc.deregisterAfter {
+		return
+	}
+
+	level.Warn(m.logger).Log(
+		"check", id,
+		"key", mc.key.Name(),
+		"msg", "check has been critical past deregister_critical_after, removing key",
+	)
+	m.registry.Remove(mc.key)
+	m.Deregister(id)
+}
+
+func deregisterAfterOrDefault(d time.Duration) time.Duration {
+	if d <= 0 {
+		return DefaultDeregisterCriticalAfter
+	}
+	return d
+}
@@ -0,0 +1,61 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/SimonRichardson/alchemy/pkg/cluster/registry"
+)
+
+const defaultHTTPTimeout = 5 * time.Second
+
+// RegisterHTTP attaches a check to key that periodically GETs url and
+// expects a 2xx response. A timeout of zero uses defaultHTTPTimeout, and a
+// deregisterAfter of zero uses DefaultDeregisterCriticalAfter.
+func (m *Manager) RegisterHTTP(key registry.Key, id, url string, interval, timeout, deregisterAfter time.Duration) {
+	if timeout <= 0 {
+		timeout = defaultHTTPTimeout
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	mc := &managedCheck{
+		key:             key,
+		deregisterAfter: deregisterAfterOrDefault(deregisterAfter),
+		cancel:          cancel,
+	}
+	m.register(id, mc)
+
+	client := &http.Client{Timeout: timeout}
+	go m.runHTTP(ctx, id, mc, client, url, interval)
+}
+
+func (m *Manager) runHTTP(ctx context.Context, id string, mc *managedCheck, client *http.Client, url string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		status, output := probeHTTP(client, url)
+		m.observe(id, mc, status, output)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func probeHTTP(client *http.Client, url string) (registry.Status, string) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return registry.Critical, fmt.Sprintf("GET %s: %s", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return registry.Critical, fmt.Sprintf("GET %s: %s", url, resp.Status)
+	}
+	return registry.Passing, fmt.Sprintf("GET %s: %s", url, resp.Status)
+}
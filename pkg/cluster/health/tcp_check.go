@@ -0,0 +1,58 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/SimonRichardson/alchemy/pkg/cluster/registry"
+)
+
+const defaultTCPTimeout = 5 * time.Second
+
+// RegisterTCP attaches a check to key that periodically dials addr and
+// expects the connection to succeed. A timeout of zero uses
+// defaultTCPTimeout, and a deregisterAfter of zero uses
+// DefaultDeregisterCriticalAfter.
+func (m *Manager) RegisterTCP(key registry.Key, id, addr string, interval, timeout, deregisterAfter time.Duration) {
+	if timeout <= 0 {
+		timeout = defaultTCPTimeout
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	mc := &managedCheck{
+		key:             key,
+		deregisterAfter: deregisterAfterOrDefault(deregisterAfter),
+		cancel:          cancel,
+	}
+	m.register(id, mc)
+
+	go m.runTCP(ctx, id, mc, addr, interval, timeout)
+}
+
+func (m *Manager) runTCP(ctx context.Context, id string, mc *managedCheck, addr string, interval, timeout time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		status, output := probeTCP(addr, timeout)
+		m.observe(id, mc, status, output)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func probeTCP(addr string, timeout time.Duration) (registry.Status, string) {
+	conn, err := net.DialTimeout("tcp", addr, timeout)
+	if err != nil {
+		return registry.Critical, fmt.Sprintf("dial %s: %s", addr, err)
+	}
+	conn.Close()
+
+	return registry.Passing, fmt.Sprintf("dial %s: ok", addr)
+}
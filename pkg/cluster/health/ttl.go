@@ -0,0 +1,66 @@
+package health
+
+import (
+	"context"
+	"time"
+
+	"github.com/SimonRichardson/alchemy/pkg/cluster/registry"
+	"github.com/pkg/errors"
+)
+
+// RegisterTTL attaches a heartbeat-style check to key: callers must call
+// Pass(id) at least once every ttl, or the check transitions to Critical.
+// A deregisterAfter of zero uses DefaultDeregisterCriticalAfter.
+func (m *Manager) RegisterTTL(key registry.Key, id string, ttl, deregisterAfter time.Duration) {
+	ctx, cancel := context.WithCancel(context.Background())
+	mc := &managedCheck{
+		key:             key,
+		deregisterAfter: deregisterAfterOrDefault(deregisterAfter),
+		cancel:          cancel,
+		passCh:          make(chan struct{}, 1),
+	}
+	m.register(id, mc)
+
+	go m.runTTL(ctx, id, mc, ttl)
+}
+
+// Pass records a successful heartbeat for the TTL check id, as driven by
+// POST /v1/check/pass/<id>.
+func (m *Manager) Pass(id string) error {
+	mc, ok := m.check(id)
+	if !ok {
+		return errors.Errorf("unknown check %q", id)
+	}
+	if mc.passCh == nil {
+		return errors.Errorf("check %q is not a TTL check", id)
+	}
+
+	select {
+	case mc.passCh <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+func (m *Manager) runTTL(ctx context.Context, id string, mc *managedCheck, ttl time.Duration) {
+	timer := time.NewTimer(ttl)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-mc.passCh:
+			m.observe(id, mc, registry.Passing, "TTL heartbeat received")
+			if !timer.Stop() {
+				<-timer.C
+			}
+			timer.Reset(ttl)
+
+		case <-timer.C:
+			m.observe(id, mc, registry.Critical, "TTL expired")
+			timer.Reset(ttl)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package peering
+
+// TokenAuthenticator implements registry.Authenticator by validating the
+// bearer token a Watch caller presents against the secret this cluster
+// minted it with (see GenerateToken).
+type TokenAuthenticator struct {
+	secret []byte
+}
+
+// NewTokenAuthenticator creates a TokenAuthenticator that accepts only
+// tokens signed with secret - the same secret passed to GenerateToken when
+// minting tokens for this cluster.
+func NewTokenAuthenticator(secret []byte) TokenAuthenticator {
+	return TokenAuthenticator{secret: secret}
+}
+
+// Authenticate decodes token and verifies its signature against a.secret,
+// returning its allowlist on success.
+func (a TokenAuthenticator) Authenticate(token string) ([]string, bool) {
+	t, err := DecodeToken(token)
+	if err != nil || !t.Verify(a.secret) {
+		return nil, false
+	}
+	return t.Allowed, true
+}
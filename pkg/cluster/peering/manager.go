@@ -0,0 +1,299 @@
+package peering
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/SimonRichardson/alchemy/pkg/cluster/registry"
+	"github.com/SimonRichardson/alchemy/pkg/cluster/registry/registrypb"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// keyPrefixFmt namespaces imported keys so they never collide with locally
+// gossiped ones, and are trivially recognisable as peer-sourced.
+const keyPrefixFmt = "peer/%s/%s"
+
+// sourceTagFmt is the value of the "source" tag applied to imported keys.
+const sourceTagFmt = "peer:%s"
+
+const (
+	minBackoff = time.Second
+	maxBackoff = time.Minute
+)
+
+type real struct {
+	mtx      sync.RWMutex
+	registry registry.Registry
+	logger   log.Logger
+	dial     func(addr string, caBundle []byte) (*grpc.ClientConn, error)
+
+	peers map[string]*peer
+}
+
+type peer struct {
+	name   string
+	token  Token
+	cancel context.CancelFunc
+
+	mtx       sync.RWMutex
+	status    Status
+	keys      map[string]registry.Key // keyed by Name(), for teardown on Delete
+	lastIndex uint64                  // highest Event index applied so far, for resume on reconnect
+}
+
+// New creates a Manager that imports services in to reg.
+func New(reg registry.Registry, logger log.Logger) Manager {
+	return &real{
+		registry: reg,
+		logger:   logger,
+		dial:     dial,
+		peers:    make(map[string]*peer),
+	}
+}
+
+// dial opens a gRPC connection to addr, authenticated by caBundle: an empty
+// caBundle dials plaintext (appropriate only on a trusted network), while a
+// non-empty one is treated as a PEM-encoded CA used to verify the
+// exporter's server certificate over TLS.
+func dial(addr string, caBundle []byte) (*grpc.ClientConn, error) {
+	if len(caBundle) == 0 {
+		return grpc.Dial(addr, grpc.WithInsecure())
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caBundle) {
+		return nil, errors.New("invalid CA bundle")
+	}
+
+	creds := credentials.NewTLS(&tls.Config{RootCAs: pool})
+	return grpc.Dial(addr, grpc.WithTransportCredentials(creds))
+}
+
+func (r *real) Establish(name string, token Token) error {
+	r.mtx.Lock()
+	if _, ok := r.peers[name]; ok {
+		r.mtx.Unlock()
+		return errors.Errorf("peer %q already established", name)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &peer{
+		name:   name,
+		token:  token,
+		cancel: cancel,
+		status: Status{Name: name, Addr: token.Addr},
+		keys:   make(map[string]registry.Key),
+	}
+	r.peers[name] = p
+	r.mtx.Unlock()
+
+	go r.run(ctx, p)
+
+	return nil
+}
+
+func (r *real) List() []string {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+
+	names := make([]string, 0, len(r.peers))
+	for name := range r.peers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (r *real) Read(name string) (Status, bool) {
+	r.mtx.RLock()
+	p, ok := r.peers[name]
+	r.mtx.RUnlock()
+	if !ok {
+		return Status{}, false
+	}
+
+	p.mtx.RLock()
+	defer p.mtx.RUnlock()
+	return p.status, true
+}
+
+func (r *real) Delete(name string) bool {
+	r.mtx.Lock()
+	p, ok := r.peers[name]
+	if ok {
+		delete(r.peers, name)
+	}
+	r.mtx.Unlock()
+
+	if !ok {
+		return false
+	}
+
+	p.cancel()
+
+	p.mtx.RLock()
+	keys := make([]registry.Key, 0, len(p.keys))
+	for _, key := range p.keys {
+		keys = append(keys, key)
+	}
+	p.mtx.RUnlock()
+
+	for _, key := range keys {
+		r.registry.Remove(key)
+	}
+
+	return true
+}
+
+// run keeps a peering stream alive, reconnecting with an exponential
+// backoff (capped at maxBackoff) whenever it drops.
+func (r *real) run(ctx context.Context, p *peer) {
+	backoff := minBackoff
+	for {
+		connected, err := r.stream(ctx, p)
+		if err != nil && ctx.Err() == nil {
+			level.Warn(r.logger).Log("peer", p.name, "err", err)
+		}
+
+		r.setConnected(p, false)
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		if connected {
+			backoff = minBackoff
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// stream dials the exporter, replays its Watch feed in to the local
+// registry and blocks until the stream ends. The returned bool reports
+// whether the stream was ever successfully established, so run knows
+// whether to reset its backoff.
+func (r *real) stream(ctx context.Context, p *peer) (bool, error) {
+	conn, err := r.dial(p.token.Addr, p.token.CABundle)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+
+	token, err := p.token.Encode()
+	if err != nil {
+		return false, err
+	}
+
+	client := registrypb.NewRegistryStreamClient(conn)
+	stream, err := client.Watch(ctx, &registrypb.WatchRequest{MinIndex: p.observedIndex(), Token: token})
+	if err != nil {
+		return false, err
+	}
+
+	r.setConnected(p, true)
+
+	for {
+		event, err := stream.Recv()
+		if err != nil {
+			return true, err
+		}
+
+		if !p.token.Allows(event.GetType()) {
+			continue
+		}
+
+		r.applyEvent(p, event)
+	}
+}
+
+// observedIndex returns the index of the last Event applied from p, so a
+// reconnect can resume from there instead of re-requesting a full snapshot.
+func (p *peer) observedIndex() uint64 {
+	p.mtx.RLock()
+	defer p.mtx.RUnlock()
+	return p.lastIndex
+}
+
+func (r *real) setConnected(p *peer, connected bool) {
+	p.mtx.Lock()
+	p.status.Connected = connected
+	if connected {
+		p.status.LastContact = time.Now()
+	}
+	p.mtx.Unlock()
+}
+
+func (r *real) applyEvent(p *peer, event *registrypb.ServiceEvent) {
+	key := newPeerKey(p.name, event)
+
+	switch event.GetOp() {
+	case registrypb.Op_ADD:
+		r.registry.Add(key)
+	case registrypb.Op_UPDATE:
+		r.registry.Update(key)
+	case registrypb.Op_REMOVE:
+		r.registry.Remove(key)
+	}
+
+	p.mtx.Lock()
+	p.status.LastContact = time.Now()
+	if event.GetOp() == registrypb.Op_REMOVE {
+		delete(p.keys, key.Name())
+	} else {
+		p.keys[key.Name()] = key
+	}
+	if index := event.GetIndex(); index > p.lastIndex {
+		p.lastIndex = index
+	}
+	p.mtx.Unlock()
+}
+
+type peerKey struct {
+	peerName string
+	event    *registrypb.ServiceEvent
+}
+
+func newPeerKey(peerName string, event *registrypb.ServiceEvent) registry.Key {
+	return &peerKey{peerName: peerName, event: event}
+}
+
+func (k *peerKey) Name() string {
+	return k.event.GetName()
+}
+
+func (k *peerKey) Type() string {
+	return k.event.GetType()
+}
+
+func (k *peerKey) Address() string {
+	return fmt.Sprintf(keyPrefixFmt, k.peerName, k.event.GetAddress())
+}
+
+func (k *peerKey) Tags() map[string]string {
+	src := k.event.GetTags()
+	tags := make(map[string]string, len(src)+1)
+	for k2, v := range src {
+		tags[k2] = v
+	}
+	tags["source"] = fmt.Sprintf(sourceTagFmt, k.peerName)
+	return tags
+}
@@ -0,0 +1,31 @@
+package peering
+
+import "time"
+
+// Manager imports services published by remote clusters in to the local
+// registry, without those remotes taking part in local gossip.
+type Manager interface {
+
+	// Establish opens a connection to the exporter described by token and
+	// begins importing its services under name. Returns an error if name
+	// is already established.
+	Establish(name string, token Token) error
+
+	// List returns the name of every peer currently established.
+	List() []string
+
+	// Read returns the status of a single peer.
+	Read(name string) (Status, bool)
+
+	// Delete tears down a peer's connection and removes its imported keys.
+	// Returns false if name isn't established.
+	Delete(name string) bool
+}
+
+// Status describes the current state of an established peer.
+type Status struct {
+	Name        string    `json:"name"`
+	Addr        string    `json:"addr"`
+	Connected   bool      `json:"connected"`
+	LastContact time.Time `json:"last_contact"`
+}
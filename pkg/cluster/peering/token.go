@@ -0,0 +1,106 @@
+package peering
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+)
+
+// Token is a bearer credential minted by an exporting cluster that lets an
+// importing cluster establish a peering connection back to it. It is
+// HMAC-signed with the secret the exporting cluster minted it with, so a
+// registry.GRPCServer validating an incoming Watch request (see
+// TokenAuthenticator) can tell a token it actually issued apart from one an
+// attacker fabricated or tampered with - without the token's allowlist
+// being merely advisory, as a plain unsigned field would be.
+type Token struct {
+	Name      string   `json:"name"`
+	Addr      string   `json:"addr"`
+	CABundle  []byte   `json:"ca_bundle,omitempty"`
+	Allowed   []string `json:"allowed,omitempty"`
+	Signature []byte   `json:"sig,omitempty"`
+}
+
+// GenerateToken mints a Token describing how to reach this cluster over
+// gRPC at grpcAddr, restricted to the given allowlist of peer types (an
+// empty allowed allows every type), and signs it with secret so that a
+// TokenAuthenticator holding the same secret can verify it later.
+func GenerateToken(name, grpcAddr string, caBundle []byte, allowed []string, secret []byte) Token {
+	t := Token{
+		Name:     name,
+		Addr:     grpcAddr,
+		CABundle: caBundle,
+		Allowed:  allowed,
+	}
+	t.Signature = t.sign(secret)
+	return t
+}
+
+// sign computes the HMAC-SHA256 of t's fields (everything but Signature
+// itself) under secret. Each field is length-prefixed, so bytes can't be
+// shifted from one field to the next without changing the signature.
+func (t Token) sign(secret []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	write := func(b []byte) {
+		var n [8]byte
+		binary.BigEndian.PutUint64(n[:], uint64(len(b)))
+		mac.Write(n[:])
+		mac.Write(b)
+	}
+	write([]byte(t.Name))
+	write([]byte(t.Addr))
+	write(t.CABundle)
+	for _, a := range t.Allowed {
+		write([]byte(a))
+	}
+	return mac.Sum(nil)
+}
+
+// Verify reports whether t was minted with secret, i.e. its Signature
+// matches what sign(secret) computes now. A token whose fields were
+// tampered with after minting, or that was never signed with secret at
+// all, fails verification.
+func (t Token) Verify(secret []byte) bool {
+	return subtle.ConstantTimeCompare(t.Signature, t.sign(secret)) == 1
+}
+
+// Encode serializes t as an opaque, URL-safe bearer token.
+func (t Token) Encode() (string, error) {
+	buf, err := json.Marshal(t)
+	if err != nil {
+		return "", errors.Wrap(err, "marshal token")
+	}
+	return base64.URLEncoding.EncodeToString(buf), nil
+}
+
+// DecodeToken parses a bearer token produced by Token.Encode.
+func DecodeToken(s string) (Token, error) {
+	buf, err := base64.URLEncoding.DecodeString(s)
+	if err != nil {
+		return Token{}, errors.Wrap(err, "decode token")
+	}
+
+	var t Token
+	if err := json.Unmarshal(buf, &t); err != nil {
+		return Token{}, errors.Wrap(err, "unmarshal token")
+	}
+	return t, nil
+}
+
+// Allows reports whether peerType may cross this token's boundary.
+func (t Token) Allows(peerType string) bool {
+	if len(t.Allowed) == 0 {
+		return true
+	}
+	for _, v := range t.Allowed {
+		if v == peerType {
+			return true
+		}
+	}
+	return false
+}
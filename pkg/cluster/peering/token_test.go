@@ -0,0 +1,93 @@
+package peering
+
+import "testing"
+
+func TestTokenAllows(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		allowed []string
+		check   string
+		want    bool
+	}{
+		{name: "empty allowlist allows everything", allowed: nil, check: "api", want: true},
+		{name: "matching type is allowed", allowed: []string{"api", "worker"}, check: "worker", want: true},
+		{name: "non-matching type is denied", allowed: []string{"api", "worker"}, check: "db", want: false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			token := GenerateToken("exporter", "localhost:1234", nil, tt.allowed, []byte("secret"))
+			if got := token.Allows(tt.check); got != tt.want {
+				t.Errorf("Allows(%q) = %v, want %v", tt.check, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenEncodeDecodeRoundTrip(t *testing.T) {
+	want := GenerateToken("exporter", "localhost:1234", []byte("ca-bundle"), []string{"api"}, []byte("secret"))
+
+	encoded, err := want.Encode()
+	if err != nil {
+		t.Fatalf("Encode() returned error: %v", err)
+	}
+
+	got, err := DecodeToken(encoded)
+	if err != nil {
+		t.Fatalf("DecodeToken() returned error: %v", err)
+	}
+
+	if got.Name != want.Name || got.Addr != want.Addr || string(got.CABundle) != string(want.CABundle) {
+		t.Errorf("DecodeToken() = %+v, want %+v", got, want)
+	}
+	if !got.Allows("api") || got.Allows("db") {
+		t.Errorf("decoded token's allowlist wasn't preserved: %+v", got)
+	}
+}
+
+func TestTokenVerify(t *testing.T) {
+	token := GenerateToken("exporter", "localhost:1234", nil, nil, []byte("secret"))
+
+	if !token.Verify([]byte("secret")) {
+		t.Error("Verify() = false for the secret the token was signed with, want true")
+	}
+	if token.Verify([]byte("wrong-secret")) {
+		t.Error("Verify() = true for the wrong secret, want false")
+	}
+
+	tampered := token
+	tampered.Allowed = []string{"anything"}
+	if tampered.Verify([]byte("secret")) {
+		t.Error("Verify() = true for a token whose fields were tampered with after signing, want false")
+	}
+}
+
+func TestTokenAuthenticator(t *testing.T) {
+	secret := []byte("secret")
+	auth := NewTokenAuthenticator(secret)
+
+	token := GenerateToken("exporter", "localhost:1234", nil, []string{"api"}, secret)
+	encoded, err := token.Encode()
+	if err != nil {
+		t.Fatalf("Encode() returned error: %v", err)
+	}
+
+	allowed, ok := auth.Authenticate(encoded)
+	if !ok {
+		t.Fatal("Authenticate() = false for a validly signed token, want true")
+	}
+	if len(allowed) != 1 || allowed[0] != "api" {
+		t.Errorf("Authenticate() allowed = %v, want [api]", allowed)
+	}
+
+	forged := GenerateToken("exporter", "localhost:1234", nil, nil, []byte("wrong-secret"))
+	forgedEncoded, err := forged.Encode()
+	if err != nil {
+		t.Fatalf("Encode() returned error: %v", err)
+	}
+	if _, ok := auth.Authenticate(forgedEncoded); ok {
+		t.Error("Authenticate() = true for a token signed with the wrong secret, want false")
+	}
+
+	if _, ok := auth.Authenticate("not a token"); ok {
+		t.Error("Authenticate() = true for garbage input, want false")
+	}
+}
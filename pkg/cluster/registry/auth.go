@@ -0,0 +1,14 @@
+package registry
+
+// Authenticator validates the bearer token a Watch caller presents and
+// reports which key types it may stream. It exists so GRPCServer can
+// enforce the token pkg/cluster/peering mints without this package having
+// to import peering, which already imports registry for Registry and
+// Event.
+type Authenticator interface {
+	// Authenticate validates token, returning the types it is allowed to
+	// stream and true if token is valid. A nil or empty allowed means
+	// every type is allowed. ok is false if token doesn't validate at
+	// all, in which case the caller should be rejected outright.
+	Authenticate(token string) (allowed []string, ok bool)
+}
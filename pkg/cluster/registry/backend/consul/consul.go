@@ -0,0 +1,174 @@
+// Package consul provides a registry.Backend that mirrors Consul's service
+// catalog: Put and Delete register and deregister an agent service, List
+// reads the catalog, and Watch long-polls it using the same blocking-query
+// index convention pkg/registry's HTTP API offers its own callers.
+package consul
+
+import (
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/SimonRichardson/alchemy/pkg/cluster/registry"
+	consulapi "github.com/hashicorp/consul/api"
+)
+
+// defaultPollTimeout bounds how long a single blocking catalog query may
+// run before Watch issues another one.
+const defaultPollTimeout = 5 * time.Minute
+
+// Backend is a registry.Backend backed by a Consul agent's catalog.
+type Backend struct {
+	client *consulapi.Client
+}
+
+// New creates a Backend that registers and queries services through
+// client.
+func New(client *consulapi.Client) *Backend {
+	return &Backend{client: client}
+}
+
+func (b *Backend) Put(key registry.Key) error {
+	return b.client.Agent().ServiceRegister(&consulapi.AgentServiceRegistration{
+		ID:      serviceID(key),
+		Name:    key.Type(),
+		Address: key.Address(),
+		Tags:    tagsToSlice(key.Tags()),
+	})
+}
+
+func (b *Backend) Delete(key registry.Key) error {
+	return b.client.Agent().ServiceDeregister(serviceID(key))
+}
+
+func (b *Backend) List(prefix string) []registry.Key {
+	services, _, err := b.client.Catalog().Services(nil)
+	if err != nil {
+		return nil
+	}
+
+	var res []registry.Key
+	for name := range services {
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+
+		entries, _, err := b.client.Catalog().Service(name, "", nil)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			res = append(res, catalogKey(entry))
+		}
+	}
+	return res
+}
+
+// Watch long-polls the catalog for services whose name has the given
+// prefix, translating each change in membership into an Event. Consul's
+// catalog doesn't distinguish add from update, so a key that is new, or
+// whose address or tags changed since the last poll, is reported as OpAdd;
+// a key that drops out between polls is reported as OpRemove.
+func (b *Backend) Watch(prefix string) <-chan registry.Event {
+	out := make(chan registry.Event, 64)
+
+	go func() {
+		seen := make(map[string]registry.Key)
+		var waitIndex uint64
+
+		for {
+			services, meta, err := b.client.Catalog().Services(&consulapi.QueryOptions{
+				WaitIndex: waitIndex,
+				WaitTime:  defaultPollTimeout,
+			})
+			if err != nil {
+				time.Sleep(time.Second)
+				continue
+			}
+			waitIndex = meta.LastIndex
+
+			current := make(map[string]registry.Key)
+			for name := range services {
+				if !strings.HasPrefix(name, prefix) {
+					continue
+				}
+				entries, _, err := b.client.Catalog().Service(name, "", nil)
+				if err != nil {
+					continue
+				}
+				for _, entry := range entries {
+					key := catalogKey(entry)
+					current[serviceID(key)] = key
+				}
+			}
+
+			for id, key := range current {
+				prev, ok := seen[id]
+				if !ok || prev.Address() != key.Address() || !reflect.DeepEqual(prev.Tags(), key.Tags()) {
+					send(out, registry.Event{Op: registry.OpAdd, Key: key})
+				}
+			}
+			for id, key := range seen {
+				if _, ok := current[id]; !ok {
+					send(out, registry.Event{Op: registry.OpRemove, Key: key})
+				}
+			}
+			seen = current
+		}
+	}()
+
+	return out
+}
+
+func send(out chan<- registry.Event, event registry.Event) {
+	select {
+	case out <- event:
+	default:
+	}
+}
+
+func serviceID(key registry.Key) string {
+	return key.Type() + "/" + key.Name()
+}
+
+func tagsToSlice(tags map[string]string) []string {
+	res := make([]string, 0, len(tags))
+	for k, v := range tags {
+		res = append(res, k+"="+v)
+	}
+	return res
+}
+
+func tagsFromSlice(tags []string) map[string]string {
+	res := make(map[string]string, len(tags))
+	for _, tag := range tags {
+		if i := strings.IndexByte(tag, '='); i >= 0 {
+			res[tag[:i]] = tag[i+1:]
+		}
+	}
+	return res
+}
+
+func catalogKey(entry *consulapi.CatalogService) registry.Key {
+	return &catalogServiceKey{
+		name:    entry.ServiceID,
+		typ:     entry.ServiceName,
+		address: entry.ServiceAddress,
+		tags:    tagsFromSlice(entry.ServiceTags),
+	}
+}
+
+// catalogServiceKey adapts a Consul catalog entry to registry.Key. Consul
+// has its own health check subsystem; it isn't surfaced here, so
+// SetStatus/Health are driven entirely by pkg/cluster/health instead.
+type catalogServiceKey struct {
+	name    string
+	typ     string
+	address string
+	tags    map[string]string
+}
+
+func (k *catalogServiceKey) Name() string            { return k.name }
+func (k *catalogServiceKey) Type() string            { return k.typ }
+func (k *catalogServiceKey) Address() string         { return k.address }
+func (k *catalogServiceKey) Tags() map[string]string { return k.tags }
@@ -0,0 +1,164 @@
+// Package etcd provides a registry.Backend backed by etcd. Keys are
+// registered under a lease: Put grants a lease for ttl and attaches it to
+// the key's storage entry, then keeps the lease alive for as long as the
+// key stays in the registry. If the process holding the lease dies without
+// calling Delete, the lease expires and etcd removes the entry for us,
+// which Watch then reports as an OpRemove - the same failure mode Consul's
+// TTL checks guard against, but enforced by etcd itself rather than by us
+// polling for missed heartbeats.
+package etcd
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SimonRichardson/alchemy/pkg/cluster/registry"
+	"github.com/pkg/errors"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// DefaultLeaseTTL is used when Backend is constructed without an explicit
+// TTL. Like DefaultDeregisterCriticalAfter it bounds how long a dead
+// process's registration can linger, but it is much shorter (30s rather
+// than 72h) since an expired lease is the only signal etcd gives us.
+const DefaultLeaseTTL = 30 * time.Second
+
+// Backend is a registry.Backend backed by an etcd cluster.
+type Backend struct {
+	client *clientv3.Client
+	prefix string
+	ttl    time.Duration
+
+	mtx    sync.Mutex
+	leases map[string]clientv3.LeaseID // keyed by storage key
+	cancel map[string]context.CancelFunc
+}
+
+// New creates a Backend that stores keys under prefix in the cluster
+// reachable via client. A ttl of zero uses DefaultLeaseTTL.
+func New(client *clientv3.Client, prefix string, ttl time.Duration) *Backend {
+	if ttl <= 0 {
+		ttl = DefaultLeaseTTL
+	}
+	return &Backend{
+		client: client,
+		prefix: strings.TrimSuffix(prefix, "/"),
+		ttl:    ttl,
+		leases: make(map[string]clientv3.LeaseID),
+		cancel: make(map[string]context.CancelFunc),
+	}
+}
+
+func (b *Backend) Put(key registry.Key) error {
+	sk := b.storageKey(key)
+
+	lease, err := b.client.Grant(context.Background(), int64(b.ttl.Seconds()))
+	if err != nil {
+		return errors.Wrap(err, "grant lease")
+	}
+
+	value, err := json.Marshal(encodeKey(key))
+	if err != nil {
+		return errors.Wrap(err, "marshal key")
+	}
+
+	if _, err := b.client.Put(context.Background(), sk, string(value), clientv3.WithLease(lease.ID)); err != nil {
+		return errors.Wrap(err, "put key")
+	}
+
+	keepAlive, err := b.client.KeepAlive(context.Background(), lease.ID)
+	if err != nil {
+		return errors.Wrap(err, "keep lease alive")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go drainKeepAlive(ctx, keepAlive)
+
+	b.mtx.Lock()
+	if prev, ok := b.cancel[sk]; ok {
+		prev()
+	}
+	b.leases[sk] = lease.ID
+	b.cancel[sk] = cancel
+	b.mtx.Unlock()
+
+	return nil
+}
+
+func (b *Backend) Delete(key registry.Key) error {
+	sk := b.storageKey(key)
+
+	b.mtx.Lock()
+	if cancel, ok := b.cancel[sk]; ok {
+		cancel()
+		delete(b.cancel, sk)
+	}
+	delete(b.leases, sk)
+	b.mtx.Unlock()
+
+	_, err := b.client.Delete(context.Background(), sk)
+	return errors.Wrap(err, "delete key")
+}
+
+func (b *Backend) List(prefix string) []registry.Key {
+	resp, err := b.client.Get(context.Background(), b.storagePrefix(prefix), clientv3.WithPrefix())
+	if err != nil {
+		return nil
+	}
+
+	var res []registry.Key
+	for _, kv := range resp.Kvs {
+		if key, ok := decodeValue(kv.Value); ok {
+			res = append(res, key)
+		}
+	}
+	return res
+}
+
+func (b *Backend) Watch(prefix string) <-chan registry.Event {
+	out := make(chan registry.Event, 64)
+
+	go func() {
+		for resp := range b.client.Watch(context.Background(), b.storagePrefix(prefix), clientv3.WithPrefix(), clientv3.WithPrevKV()) {
+			for _, ev := range resp.Events {
+				event, ok := decodeEvent(ev)
+				if !ok {
+					continue
+				}
+				select {
+				case out <- event:
+				default:
+				}
+			}
+		}
+	}()
+
+	return out
+}
+
+func (b *Backend) storageKey(key registry.Key) string {
+	return b.prefix + "/" + key.Type() + "/" + key.Address() + "/" + key.Name()
+}
+
+func (b *Backend) storagePrefix(prefix string) string {
+	return b.prefix + "/" + prefix
+}
+
+// drainKeepAlive consumes a lease's keepalive responses until ctx is
+// cancelled (on Delete) or the channel is closed by the client (the lease
+// expired or the connection dropped).
+func drainKeepAlive(ctx context.Context, ch <-chan *clientv3.LeaseKeepAliveResponse) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case _, ok := <-ch:
+			if !ok {
+				return
+			}
+		}
+	}
+}
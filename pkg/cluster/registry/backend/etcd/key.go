@@ -0,0 +1,59 @@
+package etcd
+
+import (
+	"encoding/json"
+
+	"github.com/SimonRichardson/alchemy/pkg/cluster/registry"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// wireKey is the JSON shape a registry.Key is stored as.
+type wireKey struct {
+	KeyName    string            `json:"name"`
+	KeyType    string            `json:"type"`
+	KeyAddress string            `json:"address"`
+	KeyTags    map[string]string `json:"tags,omitempty"`
+}
+
+func (k *wireKey) Name() string            { return k.KeyName }
+func (k *wireKey) Type() string            { return k.KeyType }
+func (k *wireKey) Address() string         { return k.KeyAddress }
+func (k *wireKey) Tags() map[string]string { return k.KeyTags }
+
+func encodeKey(key registry.Key) wireKey {
+	return wireKey{
+		KeyName:    key.Name(),
+		KeyType:    key.Type(),
+		KeyAddress: key.Address(),
+		KeyTags:    key.Tags(),
+	}
+}
+
+func decodeValue(value []byte) (registry.Key, bool) {
+	var wk wireKey
+	if err := json.Unmarshal(value, &wk); err != nil {
+		return nil, false
+	}
+	return &wk, true
+}
+
+func decodeEvent(ev *clientv3.Event) (registry.Event, bool) {
+	if ev.Type == clientv3.EventTypeDelete {
+		wk, ok := decodeValue(ev.PrevKv.GetValue())
+		if !ok {
+			return registry.Event{}, false
+		}
+		return registry.Event{Op: registry.OpRemove, Key: wk}, true
+	}
+
+	wk, ok := decodeValue(ev.Kv.Value)
+	if !ok {
+		return registry.Event{}, false
+	}
+
+	op := registry.OpAdd
+	if ev.IsModify() {
+		op = registry.OpUpdate
+	}
+	return registry.Event{Op: op, Key: wk}, true
+}
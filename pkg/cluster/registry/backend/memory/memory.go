@@ -0,0 +1,96 @@
+// Package memory provides a registry.Backend that keeps every key in a
+// local map. It exists so that callers which want NewWithBackend's seed-
+// from-List-then-apply-Watch behaviour can get it without running an
+// external store.
+package memory
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/SimonRichardson/alchemy/pkg/cluster/registry"
+)
+
+// Backend is an in-process, single-node registry.Backend. It does not
+// propagate anything between processes; use it for tests, or for a
+// Registry that doesn't need one (see registry.New).
+type Backend struct {
+	mtx  sync.RWMutex
+	keys map[string]registry.Key
+
+	subMtx      sync.Mutex
+	subscribers []memorySubscriber
+}
+
+type memorySubscriber struct {
+	prefix string
+	ch     chan registry.Event
+}
+
+// New creates a Backend with no keys.
+func New() *Backend {
+	return &Backend{
+		keys: make(map[string]registry.Key),
+	}
+}
+
+func (b *Backend) Put(key registry.Key) error {
+	b.mtx.Lock()
+	b.keys[storageKey(key)] = key
+	b.mtx.Unlock()
+
+	b.publish(registry.Event{Op: registry.OpAdd, Key: key})
+	return nil
+}
+
+func (b *Backend) Delete(key registry.Key) error {
+	b.mtx.Lock()
+	delete(b.keys, storageKey(key))
+	b.mtx.Unlock()
+
+	b.publish(registry.Event{Op: registry.OpRemove, Key: key})
+	return nil
+}
+
+func (b *Backend) List(prefix string) []registry.Key {
+	b.mtx.RLock()
+	defer b.mtx.RUnlock()
+
+	var res []registry.Key
+	for k, key := range b.keys {
+		if strings.HasPrefix(k, prefix) {
+			res = append(res, key)
+		}
+	}
+	return res
+}
+
+func (b *Backend) Watch(prefix string) <-chan registry.Event {
+	ch := make(chan registry.Event, 64)
+
+	b.subMtx.Lock()
+	b.subscribers = append(b.subscribers, memorySubscriber{prefix: prefix, ch: ch})
+	b.subMtx.Unlock()
+
+	return ch
+}
+
+func (b *Backend) publish(event registry.Event) {
+	b.subMtx.Lock()
+	defer b.subMtx.Unlock()
+
+	k := storageKey(event.Key)
+	for _, sub := range b.subscribers {
+		if !strings.HasPrefix(k, sub.prefix) {
+			continue
+		}
+		select {
+		case sub.ch <- event:
+		default:
+		}
+	}
+}
+
+func storageKey(key registry.Key) string {
+	return key.Type() + "/" + key.Address() + "/" + key.Name()
+}
@@ -0,0 +1,100 @@
+package registry
+
+import (
+	"github.com/SimonRichardson/alchemy/pkg/cluster/registry/registrypb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// GRPCServer adapts a Registry to the registrypb.RegistryStreamServer
+// interface, so that Subscribe can be consumed over gRPC as well as SSE.
+type GRPCServer struct {
+	registrypb.UnimplementedRegistryStreamServer
+
+	registry Registry
+	auth     Authenticator
+}
+
+// NewGRPCServer creates a GRPCServer wrapping registry. auth validates the
+// bearer token presented by every Watch request; a nil auth accepts every
+// request unauthenticated, which is only appropriate when the gRPC port
+// isn't reachable by anything outside a trusted network.
+func NewGRPCServer(registry Registry, auth Authenticator) *GRPCServer {
+	return &GRPCServer{registry: registry, auth: auth}
+}
+
+// Watch streams ServiceEvents matching req to stream until the client
+// disconnects or the stream errors. A fresh request (MinIndex 0) always
+// opens with a snapshot of every currently matching key as synthetic ADD
+// events, so an importer that just established a peering connection (see
+// pkg/cluster/peering) doesn't miss whatever already existed on connect.
+//
+// If the server was built with an Authenticator, req.Token is validated
+// before anything is sent, and every event is narrowed down to the token's
+// allowlist regardless of req.Type - a caller can't broaden what it sees
+// by simply asking for a type its token doesn't grant.
+func (s *GRPCServer) Watch(req *registrypb.WatchRequest, stream registrypb.RegistryStream_WatchServer) error {
+	var allowed []string
+	if s.auth != nil {
+		a, ok := s.auth.Authenticate(req.GetToken())
+		if !ok {
+			return status.Error(codes.Unauthenticated, "invalid or missing peering token")
+		}
+		allowed = a
+	}
+
+	events, err := s.registry.Subscribe(stream.Context(), Filter{Type: req.GetType()}, req.GetMinIndex())
+	if err != nil {
+		return err
+	}
+
+	for event := range events {
+		if !typeAllowed(allowed, event.Key.Type()) {
+			continue
+		}
+		if err := stream.Send(eventToProto(event)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// typeAllowed reports whether keyType may be sent to a caller whose token
+// authenticated with the given allowlist. An empty allowed allows every
+// type, matching peering.Token.Allows' own empty-allowlist behaviour.
+func typeAllowed(allowed []string, keyType string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, t := range allowed {
+		if t == keyType {
+			return true
+		}
+	}
+	return false
+}
+
+func eventToProto(event Event) *registrypb.ServiceEvent {
+	return &registrypb.ServiceEvent{
+		Op:      opToProto(event.Op),
+		Name:    event.Key.Name(),
+		Type:    event.Key.Type(),
+		Address: event.Key.Address(),
+		Tags:    event.Key.Tags(),
+		Index:   event.Index,
+	}
+}
+
+func opToProto(op Op) registrypb.Op {
+	switch op {
+	case OpAdd:
+		return registrypb.Op_ADD
+	case OpUpdate:
+		return registrypb.Op_UPDATE
+	case OpRemove:
+		return registrypb.Op_REMOVE
+	default:
+		return registrypb.Op_ADD
+	}
+}
@@ -1,30 +1,111 @@
 package registry
 
 import (
+	"context"
 	"sync"
+	"time"
 
 	"github.com/SimonRichardson/alchemy/pkg/cluster/hashring"
 	"github.com/SimonRichardson/alchemy/pkg/cluster/members"
 )
 
+// anyIndexKey tracks mutations across every type, so that callers watching
+// cluster.PeerTypeAny observe a change regardless of which type triggered it.
+const anyIndexKey = ""
+
 type real struct {
 	mtx               sync.RWMutex
 	hashRings         map[string]*hashring.HashRing
 	keys              map[string]map[string]Key
 	hashFn            func([]byte) uint32
 	replicationFactor int
+	backend           Backend
+
+	indexes      map[string]uint64
+	lastMutation map[string]time.Time
+	waiters      map[string]chan struct{}
+
+	subMtx      sync.Mutex
+	nextSubID   int
+	subscribers map[int]subscriber
+
+	healthMtx sync.RWMutex
+	health    map[string]KeyHealth // keyed by Key.Name()
 }
 
+type subscriber struct {
+	filter Filter
+	ch     chan Event
+}
+
+// subscriberBuffer is how many unconsumed events a subscriber may fall
+// behind by before it is dropped, so that one slow watcher can't block
+// mutations for everyone else.
+const subscriberBuffer = 64
+
 func New(hashFn func([]byte) uint32, replicationFactor int) Registry {
-	return &real{
+	return NewWithBackend(nil, hashFn, replicationFactor)
+}
+
+// NewWithBackend is like New, but sources its initial state from backend and
+// keeps applying whatever backend.Watch reports for as long as the Registry
+// is alive. A nil backend behaves exactly like New: the ring is the only
+// source of truth, and mutations are expected to arrive via Add, Remove and
+// Update alone (typically driven by gossip, as in pkg/cluster/members).
+func NewWithBackend(backend Backend, hashFn func([]byte) uint32, replicationFactor int) Registry {
+	r := &real{
 		hashRings:         make(map[string]*hashring.HashRing),
 		keys:              make(map[string]map[string]Key),
 		hashFn:            hashFn,
 		replicationFactor: replicationFactor,
+		backend:           backend,
+		indexes:           make(map[string]uint64),
+		lastMutation:      make(map[string]time.Time),
+		waiters:           make(map[string]chan struct{}),
+		subscribers:       make(map[int]subscriber),
+		health:            make(map[string]KeyHealth),
+	}
+
+	if backend != nil {
+		for _, k := range backend.List("") {
+			r.addLocal(k)
+		}
+		go r.watchBackend(backend)
+	}
+
+	return r
+}
+
+// watchBackend applies Events observed on backend to the local ring, so
+// that mutations made by other processes sharing the same backend become
+// visible through Info, Index, Wait and Subscribe exactly like a locally
+// made Add, Remove or Update. It never returns.
+func (r *real) watchBackend(backend Backend) {
+	for event := range backend.Watch("") {
+		switch event.Op {
+		case OpRemove:
+			r.removeLocal(event.Key)
+		default:
+			r.addLocal(event.Key)
+		}
 	}
 }
 
 func (r *real) Add(key Key) bool {
+	res := r.addLocal(key)
+	if r.backend != nil {
+		// Best effort: if the write fails, the backend's own Watch never
+		// sees it and the key simply never appears for anyone but us. A
+		// caller that cares can retry the Add.
+		_ = r.backend.Put(key)
+	}
+	return res
+}
+
+// addLocal applies key to the ring without writing through to a backend. It
+// is used both by Add and by watchBackend, so that applying a backend-
+// sourced Event doesn't bounce straight back to the backend it came from.
+func (r *real) addLocal(key Key) bool {
 	r.mtx.Lock()
 	defer r.mtx.Unlock()
 
@@ -40,29 +121,67 @@ func (r *real) Add(key Key) bool {
 	if _, ok := r.keys[addr]; !ok {
 		r.keys[addr] = make(map[string]Key)
 	}
-	r.keys[addr][key.Name()] = key
+
+	name := key.Name()
+	_, existed := r.keys[addr][name]
+	r.keys[addr][name] = key
+
+	if res || !existed {
+		r.bump(OpAdd, key)
+	}
 
 	return res
 }
 
 func (r *real) Remove(key Key) bool {
+	res := r.removeLocal(key)
+	if r.backend != nil {
+		_ = r.backend.Delete(key)
+	}
+	return res
+}
+
+// removeLocal is the backend-agnostic half of Remove; see addLocal.
+func (r *real) removeLocal(key Key) bool {
 	r.mtx.Lock()
 	defer r.mtx.Unlock()
 
 	var (
 		keyType = key.Type()
 		addr    = key.Address()
+		mutated bool
 	)
 	if _, ok := r.hashRings[keyType]; ok {
 		r.hashRings[keyType].Remove(addr)
 	}
 	if keys, ok := r.keys[addr]; ok {
-		delete(keys, key.Name())
+		if _, ok := keys[key.Name()]; ok {
+			delete(keys, key.Name())
+			mutated = true
+		}
 	}
+
+	if mutated {
+		r.healthMtx.Lock()
+		delete(r.health, key.Name())
+		r.healthMtx.Unlock()
+
+		r.bump(OpRemove, key)
+	}
+
 	return true
 }
 
 func (r *real) Update(key Key) bool {
+	res := r.updateLocal(key)
+	if res && r.backend != nil {
+		_ = r.backend.Put(key)
+	}
+	return res
+}
+
+// updateLocal is the backend-agnostic half of Update; see addLocal.
+func (r *real) updateLocal(key Key) bool {
 	r.mtx.Lock()
 	defer r.mtx.Unlock()
 
@@ -83,11 +202,12 @@ func (r *real) Update(key Key) bool {
 		return false
 	}
 	r.keys[addr][name] = key
+	r.bump(OpUpdate, key)
 
 	return true
 }
 
-func (r *real) Info(s string) (Info, bool) {
+func (r *real) Info(s string, stale bool) (Info, bool) {
 	r.mtx.RLock()
 	defer r.mtx.RUnlock()
 
@@ -106,7 +226,7 @@ func (r *real) Info(s string) (Info, bool) {
 
 	keys := make(map[string][]Key)
 	for _, v := range hashes {
-		if k := r.getKeysByAddress(v); len(k) > 0 {
+		if k := r.getKeysByAddress(v, stale); len(k) > 0 {
 			keys[v] = append(keys[v], k...)
 		}
 	}
@@ -117,15 +237,262 @@ func (r *real) Info(s string) (Info, bool) {
 	}, true
 }
 
-func (r *real) getKeysByAddress(addr string) (res []Key) {
+func (r *real) getKeysByAddress(addr string, stale bool) (res []Key) {
 	if keys, ok := r.keys[addr]; ok {
 		for _, v := range keys {
+			if !stale && r.statusFor(v.Name()) == Critical {
+				continue
+			}
 			res = append(res, v)
 		}
 	}
 	return
 }
 
+// statusFor returns the last known status of the key named name, defaulting
+// to Passing if it has never reported in via SetStatus. Callers must hold
+// at least r.mtx's read lock; it takes r.healthMtx itself.
+func (r *real) statusFor(name string) Status {
+	r.healthMtx.RLock()
+	defer r.healthMtx.RUnlock()
+
+	if h, ok := r.health[name]; ok {
+		return h.Status
+	}
+	return Passing
+}
+
+func (r *real) SetStatus(keyName string, status Status, output string) bool {
+	r.mtx.RLock()
+	_, found := r.lookupByName(keyName)
+	r.mtx.RUnlock()
+	if !found {
+		return false
+	}
+
+	r.healthMtx.Lock()
+	r.health[keyName] = KeyHealth{Status: status, Output: output, LastUpdated: time.Now()}
+	r.healthMtx.Unlock()
+
+	return true
+}
+
+func (r *real) Health(keyType string) []KeyHealth {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+
+	hashRing, ok := r.hashRings[keyType]
+	if !ok {
+		return nil
+	}
+
+	var res []KeyHealth
+	if err := hashRing.Walk(func(_, addr string) error {
+		for _, k := range r.getKeysByAddress(addr, true) {
+			res = append(res, r.keyHealth(k))
+		}
+		return nil
+	}); err != nil {
+		return nil
+	}
+	return res
+}
+
+func (r *real) keyHealth(k Key) KeyHealth {
+	r.healthMtx.RLock()
+	h, ok := r.health[k.Name()]
+	r.healthMtx.RUnlock()
+
+	h.Key = k
+	if !ok {
+		h.Status = Passing
+	}
+	return h
+}
+
+// lookupByName finds a key anywhere in the registry by its Name, regardless
+// of which address it's filed under. Callers must hold at least the read
+// lock.
+func (r *real) lookupByName(name string) (Key, bool) {
+	for _, keys := range r.keys {
+		if k, ok := keys[name]; ok {
+			return k, true
+		}
+	}
+	return nil, false
+}
+
+func (r *real) Index(keyType string) uint64 {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+
+	return r.indexes[keyType]
+}
+
+func (r *real) LastMutation(keyType string) time.Time {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+
+	return r.lastMutation[keyType]
+}
+
+func (r *real) Wait(ctx context.Context, keyType string, minIndex uint64) (uint64, error) {
+	for {
+		r.mtx.Lock()
+		index := r.indexes[keyType]
+		ch, ok := r.waiters[keyType]
+		if !ok {
+			ch = make(chan struct{})
+			r.waiters[keyType] = ch
+		}
+		r.mtx.Unlock()
+
+		if index > minIndex {
+			return index, nil
+		}
+
+		select {
+		case <-ch:
+		case <-ctx.Done():
+			return index, ctx.Err()
+		}
+	}
+}
+
+// bump increments the index for key's type (and the all-types index), wakes
+// any waiters blocked on either, and publishes an Event to subscribers.
+// Callers must hold the write lock.
+func (r *real) bump(op Op, key Key) {
+	keyType := key.Type()
+	now := time.Now()
+
+	r.indexes[keyType]++
+	r.lastMutation[keyType] = now
+	r.notifyWaiters(keyType)
+
+	if keyType != anyIndexKey {
+		r.indexes[anyIndexKey]++
+		r.lastMutation[anyIndexKey] = now
+		r.notifyWaiters(anyIndexKey)
+	}
+
+	r.publish(Event{Op: op, Key: key, Index: r.indexes[keyType]})
+}
+
+// Subscribe registers a new subscriber and returns the channel it will
+// receive Events on, replaying a snapshot first if minIndex calls for one
+// (see the Registry doc). The channel is closed, and the subscriber torn
+// down, once ctx is cancelled.
+func (r *real) Subscribe(ctx context.Context, filter Filter, minIndex uint64) (<-chan Event, error) {
+	// Hold the read lock until the subscriber is registered, so no mutation
+	// can land between the snapshot and the live feed.
+	r.mtx.RLock()
+	replay := r.replayLocked(filter, minIndex)
+
+	ch := make(chan Event, subscriberBuffer+len(replay))
+	for _, event := range replay {
+		ch <- event
+	}
+
+	r.subMtx.Lock()
+	id := r.nextSubID
+	r.nextSubID++
+	r.subscribers[id] = subscriber{filter: filter, ch: ch}
+	r.subMtx.Unlock()
+	r.mtx.RUnlock()
+
+	go func() {
+		<-ctx.Done()
+
+		r.subMtx.Lock()
+		delete(r.subscribers, id)
+		r.subMtx.Unlock()
+
+		close(ch)
+	}()
+
+	return ch, nil
+}
+
+// replayLocked builds the snapshot of synthetic OpAdd events a new
+// subscriber should see before anything live, covering every type matching
+// filter whose current index is greater than minIndex. Callers must hold
+// at least r.mtx's read lock.
+func (r *real) replayLocked(filter Filter, minIndex uint64) (replay []Event) {
+	types := []string{filter.Type}
+	if filter.Type == "" {
+		types = r.typesLocked()
+	}
+
+	for _, t := range types {
+		index := r.indexes[t]
+		if index <= minIndex {
+			continue
+		}
+
+		hashRing, ok := r.hashRings[t]
+		if !ok {
+			continue
+		}
+
+		hashRing.Walk(func(_, addr string) error {
+			for _, k := range r.getKeysByAddress(addr, true) {
+				replay = append(replay, Event{Op: OpAdd, Key: k, Index: index})
+			}
+			return nil
+		})
+	}
+
+	return replay
+}
+
+// Types returns the name of every type currently known to the registry.
+func (r *real) Types() []string {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+
+	return r.typesLocked()
+}
+
+// typesLocked is the lock-free half of Types; callers must hold at least
+// the read lock.
+func (r *real) typesLocked() []string {
+	types := make([]string, 0, len(r.hashRings))
+	for t := range r.hashRings {
+		types = append(types, t)
+	}
+	return types
+}
+
+// publish fans event out to every subscriber whose filter matches. A
+// subscriber that isn't keeping up is skipped rather than blocking the
+// mutation that produced the event.
+func (r *real) publish(event Event) {
+	r.subMtx.Lock()
+	defer r.subMtx.Unlock()
+
+	for _, sub := range r.subscribers {
+		if !sub.filter.Matches(event.Key) {
+			continue
+		}
+
+		select {
+		case sub.ch <- event:
+		default:
+		}
+	}
+}
+
+// notifyWaiters closes the current waiter channel for keyType, releasing
+// anything blocked in Wait, and installs a fresh channel for the next round.
+// Callers must hold the write lock.
+func (r *real) notifyWaiters(keyType string) {
+	if ch, ok := r.waiters[keyType]; ok {
+		close(ch)
+	}
+	r.waiters[keyType] = make(chan struct{})
+}
+
 type key struct {
 	member members.Member
 }
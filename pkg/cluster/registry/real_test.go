@@ -0,0 +1,102 @@
+package registry
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// testKey is a minimal Key for exercising real's index/waiter machinery
+// without depending on pkg/cluster/members.
+type testKey struct {
+	name, typ, addr string
+}
+
+func (k testKey) Name() string            { return k.name }
+func (k testKey) Type() string            { return k.typ }
+func (k testKey) Address() string         { return k.addr }
+func (k testKey) Tags() map[string]string { return nil }
+
+func testHash(b []byte) uint32 {
+	var h uint32
+	for _, c := range b {
+		h = h*31 + uint32(c)
+	}
+	return h
+}
+
+func TestWaitReturnsImmediatelyWhenAlreadyAhead(t *testing.T) {
+	r := New(testHash, 1)
+	r.Add(testKey{name: "a", typ: "api", addr: "10.0.0.1:80"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	index, err := r.Wait(ctx, "api", 0)
+	if err != nil {
+		t.Fatalf("Wait returned error: %v", err)
+	}
+	if index != 1 {
+		t.Errorf("Wait returned index %d, want 1", index)
+	}
+}
+
+func TestWaitUnblocksOnMutation(t *testing.T) {
+	r := New(testHash, 1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	done := make(chan uint64, 1)
+	errCh := make(chan error, 1)
+	go func() {
+		index, err := r.Wait(ctx, "api", 0)
+		errCh <- err
+		done <- index
+	}()
+
+	// Give Wait a chance to register its waiter before the mutation.
+	time.Sleep(10 * time.Millisecond)
+	r.Add(testKey{name: "a", typ: "api", addr: "10.0.0.1:80"})
+
+	select {
+	case err := <-errCh:
+		if err != nil {
+			t.Fatalf("Wait returned error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Wait did not unblock after a mutation")
+	}
+
+	if index := <-done; index != 1 {
+		t.Errorf("Wait returned index %d, want 1", index)
+	}
+}
+
+func TestWaitRespectsContextCancellation(t *testing.T) {
+	r := New(testHash, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := r.Wait(ctx, "api", 0); err == nil {
+		t.Fatal("Wait returned nil error for an already-cancelled context")
+	}
+}
+
+func TestBumpIncrementsPerTypeAndAnyIndex(t *testing.T) {
+	r := New(testHash, 1)
+
+	r.Add(testKey{name: "a", typ: "api", addr: "10.0.0.1:80"})
+	r.Add(testKey{name: "b", typ: "worker", addr: "10.0.0.2:80"})
+
+	if got := r.Index("api"); got != 1 {
+		t.Errorf("Index(%q) = %d, want 1", "api", got)
+	}
+	if got := r.Index("worker"); got != 1 {
+		t.Errorf("Index(%q) = %d, want 1", "worker", got)
+	}
+	if got := r.Index(anyIndexKey); got != 2 {
+		t.Errorf("Index(anyIndexKey) = %d, want 2", got)
+	}
+}
@@ -0,0 +1,198 @@
+package registry
+
+import (
+	"context"
+	"time"
+)
+
+// Registry describes a way to add, remove and query keys that are
+// distributed across a hash ring.
+type Registry interface {
+
+	// Add inserts a key in to the registry, returning true if the
+	// underlying hash ring was mutated as a result.
+	Add(key Key) bool
+
+	// Remove takes a key out of the registry, returning true if the
+	// removal was successful.
+	Remove(key Key) bool
+
+	// Update amends an existing key in the registry, returning true if
+	// the key was found and updated.
+	Update(key Key) bool
+
+	// Info returns the current hashes and keys for a given type. Keys
+	// whose status is Critical are omitted unless stale is true.
+	Info(keyType string, stale bool) (Info, bool)
+
+	// Index returns the current monotonic index for keyType. The index
+	// is incremented every time Add, Remove or Update mutates the
+	// underlying ring for that type. An empty keyType tracks mutations
+	// across all types.
+	Index(keyType string) uint64
+
+	// LastMutation returns the time of the most recent Add, Remove or
+	// Update that changed keyType's index, or the zero Time if it has
+	// never mutated. An empty keyType mirrors Index's own all-types
+	// tracking.
+	LastMutation(keyType string) time.Time
+
+	// Wait blocks until the index for keyType is greater than minIndex,
+	// or ctx is cancelled. It returns the index observed at the point
+	// of return.
+	Wait(ctx context.Context, keyType string, minIndex uint64) (uint64, error)
+
+	// Subscribe returns a channel of Events matching filter. If minIndex
+	// is less than the current index of a type matching filter, every key
+	// currently visible for that type is replayed as a synthetic OpAdd
+	// event first, so a fresh or reconnecting caller never misses a
+	// mutation it couldn't have observed directly. A minIndex of 0 always
+	// replays. After any replay, the channel continues with new mutations
+	// made after Subscribe is called. The channel is closed when ctx is
+	// cancelled.
+	Subscribe(ctx context.Context, filter Filter, minIndex uint64) (<-chan Event, error)
+
+	// Types returns the name of every type currently known to the
+	// registry, i.e. every type at least one key has ever been Added
+	// under.
+	Types() []string
+
+	// SetStatus records the health observed for the key named keyName,
+	// returning false if no such key exists. A key with a Critical status
+	// is hidden from Info unless queried with stale (Health still reports
+	// it, with its status), and is
+	// removed from the registry entirely once it has stayed Critical for
+	// longer than deregisterCriticalAfter (see health.Manager).
+	SetStatus(keyName string, status Status, output string) bool
+
+	// Health returns the per-instance health of every key of keyType. Keys
+	// with no recorded status default to Passing.
+	Health(keyType string) []KeyHealth
+}
+
+// Status describes the health of a Key as observed by its checks.
+type Status int
+
+const (
+	// Passing means every check attached to a key is succeeding.
+	Passing Status = iota
+	// Warning means a check is in a non-fatal degraded state.
+	Warning
+	// Critical means a check has failed outright.
+	Critical
+)
+
+func (s Status) String() string {
+	switch s {
+	case Passing:
+		return "passing"
+	case Warning:
+		return "warning"
+	case Critical:
+		return "critical"
+	default:
+		return "unknown"
+	}
+}
+
+// KeyHealth describes the current health of a single key, as last recorded
+// by SetStatus.
+type KeyHealth struct {
+	Key         Key
+	Status      Status
+	Output      string
+	LastUpdated time.Time
+}
+
+// Op describes the kind of mutation that produced an Event.
+type Op int
+
+const (
+	// OpAdd is emitted when a key is newly added to the registry.
+	OpAdd Op = iota
+	// OpUpdate is emitted when an existing key is amended.
+	OpUpdate
+	// OpRemove is emitted when a key is taken out of the registry.
+	OpRemove
+)
+
+func (o Op) String() string {
+	switch o {
+	case OpAdd:
+		return "add"
+	case OpUpdate:
+		return "update"
+	case OpRemove:
+		return "remove"
+	default:
+		return "unknown"
+	}
+}
+
+// Event describes a single mutation observed by Subscribe.
+type Event struct {
+	Op    Op
+	Key   Key
+	Index uint64
+}
+
+// Filter narrows a Subscribe feed down to the types a subscriber cares
+// about. A zero-value Filter matches every type.
+type Filter struct {
+	Type string
+}
+
+// Matches reports whether key falls within f.
+func (f Filter) Matches(key Key) bool {
+	return f.Type == "" || f.Type == key.Type()
+}
+
+// Key represents a single member of the registry.
+type Key interface {
+
+	// Name returns the unique name of the key.
+	Name() string
+
+	// Type returns the type of the key.
+	Type() string
+
+	// Address returns the host:port of the key.
+	Address() string
+
+	// Tags returns any associated tags of the key.
+	Tags() map[string]string
+}
+
+// Info describes the current state of a type within the registry.
+type Info struct {
+	Hashes map[string]string
+	Keys   map[string][]Key
+}
+
+// Backend describes the persistence and consensus primitives a Registry's
+// hash ring is built on top of. The default, gossip-backed Registry (see
+// New) doesn't need one: memberlist already propagates membership between
+// nodes, so the ring is the only source of truth. A Backend lets the same
+// ring logic run over an external store instead - such as etcd or Consul -
+// where keys are written and watched through that store, and several
+// processes may be applying mutations to the same ring concurrently.
+//
+// Concrete backends live in pkg/cluster/registry/backend/{memory,etcd,consul}.
+type Backend interface {
+	// Put persists key, making it visible to every List and Watch caller
+	// sharing this backend.
+	Put(key Key) error
+
+	// Delete removes key from the backend.
+	Delete(key Key) error
+
+	// Watch streams Events for keys stored under prefix, including ones
+	// put or deleted by other processes sharing this backend. The channel
+	// is never closed; callers that need to stop watching should abandon
+	// it.
+	Watch(prefix string) <-chan Event
+
+	// List returns every key currently known to the backend stored under
+	// prefix.
+	List(prefix string) []Key
+}
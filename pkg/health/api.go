@@ -0,0 +1,58 @@
+package health
+
+import (
+	"net/http"
+
+	"github.com/SimonRichardson/alchemy/pkg/api"
+	"github.com/SimonRichardson/alchemy/pkg/cluster/health"
+	"github.com/go-kit/kit/log"
+	"github.com/gorilla/mux"
+)
+
+// APIPathCheckPass is the TTL heartbeat API URL path.
+const APIPathCheckPass = "/v1/check/pass/{id}"
+
+// API wraps a health.Manager and provides a basic HTTP API for driving TTL
+// check heartbeats.
+type API struct {
+	handler http.Handler
+	manager *health.Manager
+	logger  log.Logger
+	errors  api.Error
+}
+
+// NewAPI creates a API with the correct dependencies.
+// The API is an http.Handler and can ServeHTTP.
+//
+//	POST /v1/check/pass/{id}
+//	    Records a successful heartbeat for the TTL check id.
+//	    Returns 404 Not Found if id isn't a registered TTL check.
+func NewAPI(manager *health.Manager, logger log.Logger) *API {
+	a := &API{
+		manager: manager,
+		logger:  logger,
+		errors:  api.NewError(logger),
+	}
+	{
+		router := mux.NewRouter().StrictSlash(true)
+		router.Methods("POST", "PUT").Path(APIPathCheckPass).HandlerFunc(a.handlePass)
+		router.NotFoundHandler = http.HandlerFunc(a.errors.NotFound)
+		a.handler = router
+	}
+	return a
+}
+
+func (a *API) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	a.handler.ServeHTTP(w, r)
+}
+
+func (a *API) handlePass(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	if err := a.manager.Pass(id); err != nil {
+		a.errors.NotFound(w, r)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
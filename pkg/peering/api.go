@@ -0,0 +1,181 @@
+package peering
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/SimonRichardson/alchemy/pkg/api"
+	"github.com/SimonRichardson/alchemy/pkg/cluster/peering"
+	"github.com/go-kit/kit/log"
+	"github.com/gorilla/mux"
+)
+
+// These are the peering API URL paths.
+const (
+	APIPathPeeringToken     = "/peering/token"
+	APIPathPeeringEstablish = "/peering/establish"
+	APIPathPeeringQuery     = "/peering"
+	APIPathPeeringResource  = "/peering/{name}"
+)
+
+// API wraps a peering.Manager and provides a basic HTTP API for exporting
+// and importing services across clusters that aren't in the same gossip
+// domain.
+type API struct {
+	handler  http.Handler
+	manager  peering.Manager
+	grpcAddr string
+	caBundle []byte
+	secret   []byte
+	logger   log.Logger
+	errors   api.Error
+}
+
+// NewAPI creates a API with the correct dependencies.
+// The API is an http.Handler and can ServeHTTP.
+//
+//	POST /peering/token?name={name}&allow={type,type,...}
+//	    Mints a bearer token that the named remote cluster can use to
+//	    establish a peering connection back to this one, restricted to the
+//	    given allowlist of peer types (all types, if omitted). The token is
+//	    signed with this API's secret, so this cluster's registry.GRPCServer
+//	    can reject any Watch request that doesn't present a token signed
+//	    with the same secret.
+//
+//	POST /peering/establish?name={name}
+//	    Body is the bearer token minted by the exporting cluster. Opens a
+//	    long-lived stream that imports the exporter's matching keys under
+//	    name.
+//	    Returns 400 Bad Request if name is already established.
+//
+//	GET /peering
+//	    Lists the name of every peer currently established.
+//
+//	GET /peering/{name}
+//	    Returns the status of a single peer.
+//	    Returns 404 Not Found if the peer isn't established.
+//
+//	DELETE /peering/{name}
+//	    Tears down a peer and removes its imported keys.
+//	    Returns 404 Not Found if the peer isn't established.
+func NewAPI(manager peering.Manager, grpcAddr string, caBundle []byte, secret []byte, logger log.Logger) *API {
+	a := &API{
+		manager:  manager,
+		grpcAddr: grpcAddr,
+		caBundle: caBundle,
+		secret:   secret,
+		logger:   logger,
+		errors:   api.NewError(logger),
+	}
+	{
+		router := mux.NewRouter().StrictSlash(true)
+		router.Methods("POST").Path(APIPathPeeringToken).HandlerFunc(a.handleToken)
+		router.Methods("POST").Path(APIPathPeeringEstablish).HandlerFunc(a.handleEstablish)
+		router.Methods("GET").Path(APIPathPeeringQuery).HandlerFunc(a.handleList)
+		router.Methods("GET").Path(APIPathPeeringResource).HandlerFunc(a.handleRead)
+		router.Methods("DELETE").Path(APIPathPeeringResource).HandlerFunc(a.handleDelete)
+		router.NotFoundHandler = http.HandlerFunc(a.errors.NotFound)
+		a.handler = router
+	}
+	return a
+}
+
+func (a *API) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	a.handler.ServeHTTP(w, r)
+}
+
+func (a *API) handleToken(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		a.errors.BadRequest(w, r, "missing name")
+		return
+	}
+
+	var allowed []string
+	if allow := r.URL.Query().Get("allow"); allow != "" {
+		allowed = strings.Split(allow, ",")
+	}
+
+	token := peering.GenerateToken(name, a.grpcAddr, a.caBundle, allowed, a.secret)
+	encoded, err := token.Encode()
+	if err != nil {
+		a.errors.InternalServerError(w, r, err.Error())
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(struct {
+		Token string `json:"token"`
+	}{
+		Token: encoded,
+	}); err != nil {
+		a.errors.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (a *API) handleEstablish(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		a.errors.BadRequest(w, r, "missing name")
+		return
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		a.errors.BadRequest(w, r, err.Error())
+		return
+	}
+
+	token, err := peering.DecodeToken(strings.TrimSpace(string(body)))
+	if err != nil {
+		a.errors.BadRequest(w, r, err.Error())
+		return
+	}
+
+	if err := a.manager.Establish(name, token); err != nil {
+		a.errors.BadRequest(w, r, err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (a *API) handleList(w http.ResponseWriter, r *http.Request) {
+	if err := json.NewEncoder(w).Encode(struct {
+		Peers []string `json:"peers"`
+	}{
+		Peers: a.manager.List(),
+	}); err != nil {
+		a.errors.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (a *API) handleRead(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+
+	status, ok := a.manager.Read(name)
+	if !ok {
+		a.errors.NotFound(w, r)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(status); err != nil {
+		a.errors.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (a *API) handleDelete(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+
+	if !a.manager.Delete(name) {
+		a.errors.NotFound(w, r)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
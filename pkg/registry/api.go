@@ -1,7 +1,9 @@
 package registry
 
 import (
+	"context"
 	"encoding/json"
+	"math/rand"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -21,12 +23,22 @@ import (
 // These are the registry API URL paths.
 const (
 	APIPathServicesQuery = "/services"
+	APIPathServicesWatch = "/services/watch"
+	APIPathHealthService = "/health/service/{type}"
 )
 
 const (
 	defaultContentType = "application/json"
 )
 
+// Blocking query tuning, following the Consul convention of capping how
+// long a client can ask us to hold a connection open and jittering the
+// wait so that many clients with the same timeout don't all retry at once.
+const (
+	maxWaitDuration  = 10 * time.Minute
+	waitJitterFactor = 0.16
+)
+
 // API wraps a registry and provides a basic HTTP API.
 type API struct {
 	handler        http.Handler
@@ -43,15 +55,48 @@ type API struct {
 // NewAPI creates a API with the correct dependencies.
 // The API is an http.Handler and can ServeHTTP.
 //
-//     GET /services
-//         Returns the current list of all services according to the registry.
+//	GET /services
+//	    Returns the current list of all services according to the registry.
+//
+//	GET /services?type={type}
+//	    Returns the current list of services according to the registry that
+//	    correspond to the type.
+//	    Returns 400 Bad Request if the type is in an invalid format.
+//	    Returns 404 Not Found if the type doesn't exist.
+//
+//	GET /services?type={type}&index={index}&wait={duration}
+//	    Performs a blocking query. If the registry index for type is
+//	    already greater than index, responds immediately. Otherwise the
+//	    request blocks (up to wait, capped at 10m) until a change occurs
+//	    or the wait elapses, whichever comes first. Every response,
+//	    blocking or not, carries the current index in X-Index and the
+//	    last mutation time in X-LastContact.
+//
+//	GET /services?type={type}&peer={name}
+//	    Narrows the result down to services imported from the named peer
+//	    (see pkg/cluster/peering). Addresses not tagged with that peer are
+//	    excluded from the response.
+//
+//	GET /services?type={type}&stale=true
+//	    Includes Critical keys (see pkg/cluster/health) in the response.
+//	    By default they're filtered out.
 //
-//     GET /services?type={type}
-//         Returns the current list of services according to the registry that
-//         correspond to the type.
-//         Returns 400 Bad Request if the type is in an invalid format.
-//         Returns 404 Not Found if the type doesn't exist.
+//	GET /services/watch?type={type}&index={index}
+//	    Streams incremental registry changes for type (or every type, if
+//	    omitted) as a Server-Sent Events feed, one `event: add|update|remove`
+//	    followed by a `data: {...}` JSON payload per mutation. The feed
+//	    opens with a snapshot of every currently matching key (as
+//	    synthetic `event: add`) whose type has changed since index - an
+//	    omitted or zero index (the default for a fresh client) always
+//	    replays a full snapshot, while a reconnecting client can pass the
+//	    last index it observed to resume without replaying types that
+//	    haven't changed since. The connection stays open until the client
+//	    disconnects.
 //
+//	GET /health/service/{type}
+//	    Returns the per-instance health of every key of type, as recorded
+//	    by pkg/cluster/health: {key, status, output, lastUpdated}.
+//	    ?passing=true restricts the result to Passing instances only.
 func NewAPI(peer cluster.Peer,
 	registry registry.Registry,
 	tickerDuration time.Duration,
@@ -72,6 +117,8 @@ func NewAPI(peer cluster.Peer,
 	{
 		router := mux.NewRouter().StrictSlash(true)
 		router.Methods("GET").Path(APIPathServicesQuery).HandlerFunc(api.handleServices)
+		router.Methods("GET").Path(APIPathServicesWatch).HandlerFunc(api.handleServicesWatch)
+		router.Methods("GET").Path(APIPathHealthService).HandlerFunc(api.handleHealthService)
 		router.NotFoundHandler = http.HandlerFunc(api.errors.NotFound)
 		api.handler = router
 	}
@@ -142,12 +189,38 @@ func (a *API) handleServices(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	indexType := registryIndexType(params.Type)
+	index := a.registry.Index(indexType)
+	if params.Index > 0 && index <= params.Index {
+		ctx := r.Context()
+		if params.Wait > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, params.Wait)
+			defer cancel()
+		}
+
+		waited, err := a.registry.Wait(ctx, indexType, params.Index)
+		if err != nil && err != context.DeadlineExceeded && err != context.Canceled {
+			a.errors.InternalServerError(w, r, err.Error())
+			return
+		}
+		index = waited
+	}
+
 	services, err := a.peer.Current(params.Type)
 	if err != nil {
 		a.errors.InternalServerError(w, r, err.Error())
 		return
 	}
 
+	if !params.Stale {
+		services = filterServicesByHealth(a.registry, services)
+	}
+
+	if params.Peer != "" {
+		services = filterServicesByPeer(a.registry, services, params.Peer, params.Stale)
+	}
+
 	if params.Type != cluster.PeerTypeAny {
 		if list, ok := services[params.Type]; !ok || len(list) == 0 {
 			a.errors.NotFound(w, r)
@@ -157,15 +230,86 @@ func (a *API) handleServices(w http.ResponseWriter, r *http.Request) {
 
 	result := ServicesResult{Errors: a.errors, Params: params}
 	result.Services = services
+	result.Index = index
+	result.LastContact = a.registry.LastMutation(indexType)
 
 	// Finish
 	result.Duration = time.Since(begin).String()
 	result.EncodeTo(w)
 }
 
+// registryIndexType maps a requested PeerType on to the bucket the registry
+// tracks indexes under; cluster.PeerTypeAny watches mutations across every
+// type rather than a single one.
+func registryIndexType(t members.PeerType) string {
+	if t == cluster.PeerTypeAny {
+		return ""
+	}
+	return t.String()
+}
+
+// filterServicesByHealth drops addresses whose every key is Critical,
+// mirroring Info's own filtering so the HTTP view stays consistent with it.
+// It always filters as a non-stale Info would; stale callers skip it
+// entirely.
+func filterServicesByHealth(reg registry.Registry, services map[members.PeerType][]string) map[members.PeerType][]string {
+	filtered := make(map[members.PeerType][]string, len(services))
+	for typ, addrs := range services {
+		info, ok := reg.Info(typ.String(), false)
+		if !ok {
+			filtered[typ] = addrs
+			continue
+		}
+
+		var kept []string
+		for _, addr := range addrs {
+			if len(info.Keys[addr]) > 0 {
+				kept = append(kept, addr)
+			}
+		}
+		if len(kept) > 0 {
+			filtered[typ] = kept
+		}
+	}
+	return filtered
+}
+
+// filterServicesByPeer narrows services down to addresses tagged as having
+// been imported from peerName (see pkg/cluster/peering), by cross
+// referencing each address against the registry's own view of its tags.
+func filterServicesByPeer(reg registry.Registry, services map[members.PeerType][]string, peerName string, stale bool) map[members.PeerType][]string {
+	tag := "peer:" + peerName
+
+	filtered := make(map[members.PeerType][]string, len(services))
+	for typ, addrs := range services {
+		info, ok := reg.Info(typ.String(), stale)
+		if !ok {
+			continue
+		}
+
+		var kept []string
+		for _, addr := range addrs {
+			for _, key := range info.Keys[addr] {
+				if key.Tags()["source"] == tag {
+					kept = append(kept, addr)
+					break
+				}
+			}
+		}
+		if len(kept) > 0 {
+			filtered[typ] = kept
+		}
+	}
+	return filtered
+}
+
 // ServicesParams handles
 type ServicesParams struct {
-	Type members.PeerType
+	Type  members.PeerType
+	Index uint64
+	Wait  time.Duration
+	Peer  string
+	Stale bool
 }
 
 // DecodeFrom populates a ServicesParams from a Request.
@@ -179,15 +323,53 @@ func (p *ServicesParams) DecodeFrom(headers http.Header, values url.Values) (err
 	} else {
 		p.Type, err = cluster.ParsePeerType(typ)
 	}
+	if err != nil {
+		return
+	}
+
+	if idx := values.Get("index"); idx != "" {
+		if p.Index, err = strconv.ParseUint(idx, 10, 64); err != nil {
+			return errors.Errorf("invalid index %q", idx)
+		}
+	}
+
+	if wait := values.Get("wait"); wait != "" {
+		var d time.Duration
+		if d, err = time.ParseDuration(wait); err != nil {
+			return errors.Errorf("invalid wait %q", wait)
+		}
+		p.Wait = jitterWait(d)
+	}
+
+	p.Peer = values.Get("peer")
+
+	if stale := values.Get("stale"); stale != "" {
+		if p.Stale, err = strconv.ParseBool(stale); err != nil {
+			return errors.Errorf("invalid stale %q", stale)
+		}
+	}
+
 	return
 }
 
+// jitterWait caps d at maxWaitDuration and shaves a random amount (up to
+// waitJitterFactor) off it, so that many blocking clients with an identical
+// wait don't all wake up and retry in lockstep.
+func jitterWait(d time.Duration) time.Duration {
+	if d > maxWaitDuration {
+		d = maxWaitDuration
+	}
+	return d - time.Duration(rand.Float64()*waitJitterFactor*float64(d))
+}
+
 // ServicesResult contains statistics about the services query.
 type ServicesResult struct {
-	Errors   api.Error
-	Params   ServicesParams
-	Duration string
-	Services map[members.PeerType][]string
+	Errors      api.Error
+	Params      ServicesParams
+	Duration    string
+	Services    map[members.PeerType][]string
+	Index       uint64
+	LastContact time.Time
 }
 
 // EncodeTo encodes the Services to the HTTP response
@@ -197,6 +379,8 @@ func (r *ServicesResult) EncodeTo(w http.ResponseWriter) {
 	headers.Set(httpHeaderContentType, defaultContentType)
 	headers.Set(httpHeaderDuration, r.Duration)
 	headers.Set(httpHeaderType, r.Params.Type.String())
+	headers.Set(httpHeaderIndex, strconv.FormatUint(r.Index, 10))
+	headers.Set(httpHeaderLastContact, r.LastContact.UTC().Format(time.RFC3339))
 
 	if err := json.NewEncoder(w).Encode(struct {
 		Services map[members.PeerType][]string `json:"services"`
@@ -211,6 +395,8 @@ const (
 	httpHeaderContentType = "Content-Type"
 	httpHeaderDuration    = "X-Duration"
 	httpHeaderType        = "X-Type"
+	httpHeaderIndex       = "X-Index"
+	httpHeaderLastContact = "X-LastContact"
 )
 
 type eventAdapter struct {
@@ -0,0 +1,56 @@
+package registry
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/SimonRichardson/alchemy/pkg/cluster/registry"
+	"github.com/gorilla/mux"
+)
+
+// healthEntry is the per-instance payload returned by GET
+// /health/service/{type}.
+type healthEntry struct {
+	Key         string    `json:"key"`
+	Status      string    `json:"status"`
+	Output      string    `json:"output"`
+	LastUpdated time.Time `json:"lastUpdated"`
+}
+
+// handleHealthService returns the health of every instance of a type, as
+// last recorded by pkg/cluster/health.
+func (a *API) handleHealthService(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	keyType := mux.Vars(r)["type"]
+
+	var passingOnly bool
+	if v := r.URL.Query().Get("passing"); v != "" {
+		var err error
+		if passingOnly, err = strconv.ParseBool(v); err != nil {
+			a.errors.BadRequest(w, r, "invalid passing value "+strconv.Quote(v))
+			return
+		}
+	}
+
+	entries := a.registry.Health(keyType)
+
+	results := make([]healthEntry, 0, len(entries))
+	for _, h := range entries {
+		if passingOnly && h.Status != registry.Passing {
+			continue
+		}
+		results = append(results, healthEntry{
+			Key:         h.Key.Name(),
+			Status:      h.Status.String(),
+			Output:      h.Output,
+			LastUpdated: h.LastUpdated,
+		})
+	}
+
+	if err := json.NewEncoder(w).Encode(results); err != nil {
+		a.errors.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,82 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/SimonRichardson/alchemy/pkg/cluster/registry"
+)
+
+// handleServicesWatch streams incremental registry changes to the client
+// as Server-Sent Events, until the client disconnects.
+func (a *API) handleServicesWatch(w http.ResponseWriter, r *http.Request) {
+	var params ServicesParams
+	if err := params.DecodeFrom(r.Header, r.URL.Query()); err != nil {
+		a.errors.BadRequest(w, r, err.Error())
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		a.errors.InternalServerError(w, r, "streaming not supported")
+		return
+	}
+
+	filter := registry.Filter{Type: registryIndexType(params.Type)}
+
+	ctx := r.Context()
+	events, err := a.registry.Subscribe(ctx, filter, params.Index)
+	if err != nil {
+		a.errors.InternalServerError(w, r, err.Error())
+		return
+	}
+
+	headers := w.Header()
+	headers.Set("Content-Type", "text/event-stream")
+	headers.Set("Cache-Control", "no-cache")
+	headers.Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if err := writeServiceEvent(w, event); err != nil {
+				return
+			}
+			flusher.Flush()
+
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// serviceEvent is the JSON payload carried by each SSE `data:` line.
+type serviceEvent struct {
+	Type  string            `json:"type"`
+	Name  string            `json:"name"`
+	Addr  string            `json:"address"`
+	Tags  map[string]string `json:"tags,omitempty"`
+	Index uint64            `json:"index"`
+}
+
+func writeServiceEvent(w http.ResponseWriter, event registry.Event) error {
+	payload, err := json.Marshal(serviceEvent{
+		Type:  event.Key.Type(),
+		Name:  event.Key.Name(),
+		Addr:  event.Key.Address(),
+		Tags:  event.Key.Tags(),
+		Index: event.Index,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Op, payload)
+	return err
+}